@@ -0,0 +1,166 @@
+// Command gcp-iam-test demonstrates the gap between a Pub/Sub role grant at
+// the topic scope and the permissions it actually confers: granting
+// roles/pubsub.subscriber on a topic does not permit creating a
+// subscription against that topic, even though the project-scoped
+// equivalent does.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/pubsub/v1"
+
+	"github.com/slack/gcp-iam-test/pkg/iamprobe"
+)
+
+const (
+	// Set this to false to keep the service account and topic around for manual inspection
+	cleanup = true
+
+	// These cannot already exist within the project
+	testTopicName          = "test-topic"
+	testSubscriptionName   = "test-sub"
+	testServiceAccountName = "test-sa"
+)
+
+func main() {
+	credentialsPath := flag.String("credentials", os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"),
+		"path to a full-access service account JSON key file")
+	verifyDataPlane := flag.Bool("verify-data-plane", false,
+		"after granting the global role, also publish and receive a message as the new service account")
+	dataPlaneTimeout := flag.Duration("data-plane-timeout", 10*time.Second,
+		"how long to wait for a published message to be received when -verify-data-plane is set")
+	flag.Parse()
+
+	if *credentialsPath == "" {
+		log.Fatalf("-credentials (or GOOGLE_APPLICATION_CREDENTIALS) must be set to a full-access JWT credential file.")
+	}
+
+	ctx := context.Background()
+
+	jwt, err := iamprobe.JWTFromFile(*credentialsPath)
+	if err != nil {
+		log.Fatalf("Failed to load JWT from file: %s\n", err)
+	}
+	projectID := jwt.ProjectID
+
+	prober, err := iamprobe.NewProberFromSource(ctx, iamprobe.FileCredentials{Path: *credentialsPath}, projectID)
+	if err != nil {
+		log.Fatalf("Failed to create prober: %s\n", err)
+	}
+
+	// Create a new service account
+	serviceAccount, err := prober.CreateServiceAccount(ctx, testServiceAccountName)
+	if err != nil {
+		log.Printf("Failed to create service account: %s\n", err)
+	}
+	if cleanup {
+		defer prober.DeleteServiceAccount(ctx, serviceAccount)
+	}
+
+	// Create a new topic
+	topic, err := prober.CreateTopic(ctx, testTopicName)
+	if err != nil {
+		log.Printf("Failed to create topic: %s\n", err)
+	}
+	if cleanup {
+		defer prober.DeleteTopic(ctx, topic)
+	}
+
+	// Grant the service account permissions on the new topic
+	if err := prober.GrantTopicRole(ctx, topic, serviceAccount, "subscriber"); err != nil {
+		log.Printf("Failed to grant permissions on topic: %s\n", err)
+	}
+
+	// Mint a key for the service account and build a second prober acting
+	// as it, all in memory -- no key file, no GOOGLE_APPLICATION_CREDENTIALS
+	// swap.
+	key, err := prober.CreateServiceAccountKey(ctx, serviceAccount)
+	if err != nil {
+		log.Printf("Failed to create service account key: %s\n", err)
+	}
+	newSource, err := iamprobe.CredentialSourceFromKey(key)
+	if err != nil {
+		log.Printf("Failed to build credential source from key: %s\n", err)
+	}
+	newProber, err := iamprobe.NewProberFromSource(ctx, newSource, projectID)
+	if err != nil {
+		log.Printf("Failed to create prober for new service account: %s\n", err)
+	}
+
+	// Test permissions
+	topicPerms, err := newProber.ProbeTopic(ctx, topic)
+	if err != nil {
+		// This fails.
+		// Error 400: The IAM operation failed with a non-retryable error: Unknown error. See https://cloud.google.com/pubsub/access_control for more information., badRequest
+		log.Printf("Failed to get permissions on topic: %s\n", err)
+	}
+	if topicPerms != nil {
+		for _, perm := range topicPerms.Allowed {
+			log.Printf("Allowed: %v\n", perm)
+		}
+	}
+
+	// Create a new Pub/Sub subscription using only the topic-scoped role.
+	testSub, err := newProber.CreateSubscription(ctx, testSubscriptionName, topic)
+	if err != nil {
+		// This fails.
+		// Error 403: User not authorized to perform this action., forbidden
+		log.Printf("Failed to create subscription with only a topic role: %s\n", err)
+	}
+	if testSub != nil {
+		if cleanup {
+			defer newProber.DeleteSubscription(ctx, testSub)
+		}
+		log.Println("SUCCESS using topic role!")
+		log.Printf("%+v", testSub)
+	}
+
+	// Show that granting a global role does indeed give the Service Account the needed permission to subscribe
+	if err := prober.GrantProjectRole(ctx, serviceAccount, "pubsub.subscriber"); err != nil {
+		log.Printf("Failed to grant global permission: %s\n", err)
+	}
+
+	// Test permissions
+	projectPerms, err := newProber.ProbeProject(ctx)
+	if err != nil {
+		log.Printf("Failed to get project permissions: %s\n", err)
+	}
+	if projectPerms != nil {
+		for _, perm := range projectPerms.Allowed {
+			log.Printf("Allowed: %v\n", perm)
+		}
+	}
+
+	// Try again to create a new Pub/Sub subscription
+	testSub2, err := newProber.CreateSubscription(ctx, testSubscriptionName+"2", topic)
+	if err != nil {
+		log.Printf("Failed to create subscription, even with global role: %s\n", err)
+	}
+	if testSub2 != nil {
+		if cleanup {
+			defer newProber.DeleteSubscription(ctx, testSub2)
+		}
+		log.Println("SUCCESS using global role!")
+		log.Printf("%+v", testSub2)
+
+		if *verifyDataPlane {
+			dpHTTPClient, err := newSource.HTTPClient(ctx, pubsub.PubsubScope)
+			if err != nil {
+				log.Printf("Failed to build HTTP client for data-plane verify: %s\n", err)
+			} else if dpResult, err := iamprobe.VerifyDataPlane(ctx, projectID, topic.Name, testSub2.Name, *dataPlaneTimeout,
+				option.WithHTTPClient(dpHTTPClient)); err != nil {
+				log.Printf("Failed to verify data-plane access: %s\n", err)
+			} else if dpResult.Err != nil {
+				log.Printf("Data-plane verify denied: %s\n", dpResult.Err)
+			} else {
+				log.Printf("Data-plane verify: published=%v received=%v\n", dpResult.Published, dpResult.Received)
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Command iamscenario runs a declarative IAM test matrix (see
+// pkg/scenario) against a real GCP project and reports the results as
+// JUnit XML and JSON.
+package main
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/slack/gcp-iam-test/pkg/iamprobe"
+	"github.com/slack/gcp-iam-test/pkg/scenario"
+)
+
+func main() {
+	credentialsPath := flag.String("credentials", os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"),
+		"path to a full-access service account JSON key file")
+	scenarioPath := flag.String("scenario", "", "path to a scenario YAML file")
+	junitOut := flag.String("junit", "", "path to write a JUnit XML report to (optional)")
+	jsonOut := flag.String("json", "", "path to write a JSON report to (optional)")
+	flag.Parse()
+
+	if *credentialsPath == "" {
+		log.Fatalf("-credentials (or GOOGLE_APPLICATION_CREDENTIALS) must be set to a full-access JWT credential file.")
+	}
+	if *scenarioPath == "" {
+		log.Fatalf("-scenario must be set to a scenario YAML file.")
+	}
+
+	ctx := context.Background()
+
+	s, err := scenario.LoadFile(*scenarioPath)
+	if err != nil {
+		log.Fatalf("Failed to load scenario: %s\n", err)
+	}
+
+	jwt, err := iamprobe.JWTFromFile(*credentialsPath)
+	if err != nil {
+		log.Fatalf("Failed to load JWT from file: %s\n", err)
+	}
+
+	prober, err := iamprobe.NewProberFromSource(ctx, iamprobe.FileCredentials{Path: *credentialsPath}, jwt.ProjectID)
+	if err != nil {
+		log.Fatalf("Failed to create prober: %s\n", err)
+	}
+
+	report, err := scenario.NewExecutor(prober).Run(ctx, s)
+	if err != nil {
+		log.Fatalf("Failed to run scenario %q: %s\n", s.Name, err)
+	}
+
+	if *junitOut != "" {
+		out, err := report.JUnitXML()
+		if err != nil {
+			log.Fatalf("Failed to render JUnit report: %s\n", err)
+		}
+		if err := ioutil.WriteFile(*junitOut, out, 0644); err != nil {
+			log.Fatalf("Failed to write JUnit report: %s\n", err)
+		}
+	}
+	if *jsonOut != "" {
+		out, err := report.JSON()
+		if err != nil {
+			log.Fatalf("Failed to render JSON report: %s\n", err)
+		}
+		if err := ioutil.WriteFile(*jsonOut, out, 0644); err != nil {
+			log.Fatalf("Failed to write JSON report: %s\n", err)
+		}
+	}
+
+	for _, step := range report.Steps {
+		if step.Passed {
+			log.Printf("PASS %s\n", step.Name)
+			continue
+		}
+		log.Printf("FAIL %s\n", step.Name)
+		for _, failure := range step.Failures {
+			log.Printf("     %s\n", failure)
+		}
+	}
+
+	if !report.Passed() {
+		os.Exit(1)
+	}
+}
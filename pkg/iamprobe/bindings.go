@@ -0,0 +1,88 @@
+package iamprobe
+
+import (
+	cloudres "google.golang.org/api/cloudresourcemanager/v1"
+	iam "google.golang.org/api/iam/v1"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+// conditionalPolicyVersion is the policy.version that must be set whenever
+// a binding carries an IAM Condition. See
+// https://cloud.google.com/iam/docs/policies#versions
+const conditionalPolicyVersion = 3
+
+func exprEqual(a, b *cloudres.Expr) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Expression == b.Expression
+}
+
+func pubsubExprEqual(a, b *pubsub.Expr) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Expression == b.Expression
+}
+
+func collapseBindings(bindings []*cloudres.Binding, role string, condition *cloudres.Expr) *cloudres.Binding {
+	// Collapse bindings into single role entries, unless IAM Conditions are
+	// involved: a role bound with two different conditions must stay in two
+	// separate bindings.
+	for _, binding := range bindings {
+		if binding.Role == roleResourcePrefix+role && exprEqual(binding.Condition, condition) {
+			return binding
+		}
+	}
+	return nil
+}
+
+func collapsePubsubBindings(bindings []*pubsub.Binding, role string, condition *pubsub.Expr) *pubsub.Binding {
+	// Collapse bindings into single role entries, unless IAM Conditions are
+	// involved: a role bound with two different conditions must stay in two
+	// separate bindings.
+	for _, binding := range bindings {
+		if binding.Role == roleResourcePrefix+role && pubsubExprEqual(binding.Condition, condition) {
+			return binding
+		}
+	}
+	return nil
+}
+
+func addMemberToPolicy(policy *cloudres.Policy, sa *iam.ServiceAccount, role string, condition *cloudres.Expr) {
+	binding := collapseBindings(policy.Bindings, role, condition)
+	if binding != nil {
+		// If the binding is not nil, append the service account to the list of members.
+		binding.Members = append(binding.Members, saBindingPrefix+sa.Email)
+	} else {
+		// Otherwise, create a new binding with the member.
+		binding = &cloudres.Binding{
+			Members:   []string{saBindingPrefix + sa.Email},
+			Role:      roleResourcePrefix + role,
+			Condition: condition,
+		}
+		policy.Bindings = append(policy.Bindings, binding)
+	}
+	if condition != nil {
+		policy.Version = conditionalPolicyVersion
+	}
+}
+
+func addMemberToPubSubPolicy(policy *pubsub.Policy, sa *iam.ServiceAccount, role string, condition *pubsub.Expr) {
+	binding := collapsePubsubBindings(policy.Bindings, role, condition)
+	if binding != nil {
+		// If the binding is not nil, append the service account to the list of members.
+		binding.Members = append(binding.Members, saBindingPrefix+sa.Email)
+	} else {
+		// Otherwise, create a new binding with the member.
+		binding = &pubsub.Binding{
+			Members:   []string{saBindingPrefix + sa.Email},
+			Role:      roleResourcePrefix + role,
+			Condition: condition,
+		}
+		policy.Bindings = append(policy.Bindings, binding)
+	}
+	if condition != nil {
+		policy.Version = conditionalPolicyVersion
+	}
+}
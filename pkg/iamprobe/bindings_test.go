@@ -0,0 +1,86 @@
+package iamprobe
+
+import (
+	"testing"
+
+	cloudres "google.golang.org/api/cloudresourcemanager/v1"
+	iam "google.golang.org/api/iam/v1"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+func TestAddMemberToPolicyCollapsesSameRoleAndCondition(t *testing.T) {
+	sa := &iam.ServiceAccount{Email: "sa@example.iam.gserviceaccount.com"}
+	policy := &cloudres.Policy{}
+
+	addMemberToPolicy(policy, sa, "viewer", nil)
+	addMemberToPolicy(policy, sa, "viewer", nil)
+
+	if len(policy.Bindings) != 1 {
+		t.Fatalf("got %d bindings, want 1", len(policy.Bindings))
+	}
+	if got := len(policy.Bindings[0].Members); got != 2 {
+		t.Fatalf("got %d members, want 2", got)
+	}
+}
+
+func TestAddMemberToPolicyKeepsDistinctConditionsSeparate(t *testing.T) {
+	sa := &iam.ServiceAccount{Email: "sa@example.iam.gserviceaccount.com"}
+	policy := &cloudres.Policy{}
+
+	addMemberToPolicy(policy, sa, "viewer", nil)
+	addMemberToPolicy(policy, sa, "viewer", &cloudres.Expr{Expression: `resource.name.endsWith("test-topic")`})
+
+	if len(policy.Bindings) != 2 {
+		t.Fatalf("got %d bindings, want 2 (unconditional and conditional bindings must not collapse)", len(policy.Bindings))
+	}
+	if policy.Version != conditionalPolicyVersion {
+		t.Fatalf("Version = %d, want %d once a conditional binding exists", policy.Version, conditionalPolicyVersion)
+	}
+}
+
+func TestAddMemberToPolicyCollapsesSameCondition(t *testing.T) {
+	sa := &iam.ServiceAccount{Email: "sa@example.iam.gserviceaccount.com"}
+	policy := &cloudres.Policy{}
+	expression := `resource.name.endsWith("test-topic")`
+
+	addMemberToPolicy(policy, sa, "viewer", &cloudres.Expr{Expression: expression})
+	addMemberToPolicy(policy, sa, "viewer", &cloudres.Expr{Expression: expression})
+
+	if len(policy.Bindings) != 1 {
+		t.Fatalf("got %d bindings, want 1 (same role+condition should collapse)", len(policy.Bindings))
+	}
+	if got := len(policy.Bindings[0].Members); got != 2 {
+		t.Fatalf("got %d members, want 2", got)
+	}
+}
+
+func TestAddMemberToPubSubPolicyKeepsDistinctConditionsSeparate(t *testing.T) {
+	sa := &iam.ServiceAccount{Email: "sa@example.iam.gserviceaccount.com"}
+	policy := &pubsub.Policy{}
+
+	addMemberToPubSubPolicy(policy, sa, "pubsub.subscriber", nil)
+	addMemberToPubSubPolicy(policy, sa, "pubsub.subscriber", &pubsub.Expr{Expression: `resource.name.endsWith("test-topic")`})
+
+	if len(policy.Bindings) != 2 {
+		t.Fatalf("got %d bindings, want 2 (unconditional and conditional bindings must not collapse)", len(policy.Bindings))
+	}
+	if policy.Version != conditionalPolicyVersion {
+		t.Fatalf("Version = %d, want %d once a conditional binding exists", policy.Version, conditionalPolicyVersion)
+	}
+}
+
+func TestAddMemberToPubSubPolicyCollapsesSameCondition(t *testing.T) {
+	sa := &iam.ServiceAccount{Email: "sa@example.iam.gserviceaccount.com"}
+	policy := &pubsub.Policy{}
+	expression := `resource.name.endsWith("test-topic")`
+
+	addMemberToPubSubPolicy(policy, sa, "pubsub.subscriber", &pubsub.Expr{Expression: expression})
+	addMemberToPubSubPolicy(policy, sa, "pubsub.subscriber", &pubsub.Expr{Expression: expression})
+
+	if len(policy.Bindings) != 1 {
+		t.Fatalf("got %d bindings, want 1 (same role+condition should collapse)", len(policy.Bindings))
+	}
+	if got := len(policy.Bindings[0].Members); got != 2 {
+		t.Fatalf("got %d members, want 2", got)
+	}
+}
@@ -0,0 +1,91 @@
+package iamprobe
+
+import (
+	"context"
+	"fmt"
+
+	cloudres "google.golang.org/api/cloudresourcemanager/v1"
+	iam "google.golang.org/api/iam/v1"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+// Scope identifies where a conditional role grant is applied.
+type Scope int
+
+// Scopes a conditional role grant can target.
+const (
+	ScopeProject Scope = iota
+	ScopeTopic
+	ScopeSubscription
+)
+
+// Condition is an IAM Conditions expression attached to a role binding,
+// e.g. a CEL expression like `resource.name.endsWith('test-topic')`. See
+// https://cloud.google.com/iam/docs/conditions-overview
+type Condition struct {
+	Expression  string
+	Title       string
+	Description string
+}
+
+// GrantConditional grants role to sa at scope with condition attached to
+// the binding. Unlike GrantProjectRole, GrantTopicRole, and
+// GrantSubscriptionRole, the same (role, member) pair may be bound more
+// than once as long as each binding has a distinct condition.
+// resourceName is the full resource name to bind against (a topic's or
+// subscription's Name); it is ignored for ScopeProject.
+func (p *Prober) GrantConditional(ctx context.Context, scope Scope, resourceName string, sa *iam.ServiceAccount, role string, condition *Condition) error {
+	switch scope {
+	case ScopeProject:
+		currPolicy, err := p.projects.GetIamPolicy(p.ProjectID, &cloudres.GetIamPolicyRequest{
+			Options: &cloudres.GetPolicyOptions{RequestedPolicyVersion: conditionalPolicyVersion},
+		}).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("getting project iam policy: %w", err)
+		}
+		addMemberToPolicy(currPolicy, sa, role, condition.toExpr())
+		if _, err := p.projects.SetIamPolicy(p.ProjectID, &cloudres.SetIamPolicyRequest{Policy: currPolicy}).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("setting project iam policy: %w", err)
+		}
+		return nil
+
+	case ScopeTopic:
+		currPolicy, err := p.topics.GetIamPolicy(resourceName).OptionsRequestedPolicyVersion(conditionalPolicyVersion).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("getting iam policy for topic %q: %w", resourceName, err)
+		}
+		addMemberToPubSubPolicy(currPolicy, sa, pubsubRolePrefix+role, condition.toPubsubExpr())
+		if _, err := p.topics.SetIamPolicy(resourceName, &pubsub.SetIamPolicyRequest{Policy: currPolicy}).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("setting iam policy for topic %q: %w", resourceName, err)
+		}
+		return nil
+
+	case ScopeSubscription:
+		currPolicy, err := p.subscriptions.GetIamPolicy(resourceName).OptionsRequestedPolicyVersion(conditionalPolicyVersion).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("getting iam policy for subscription %q: %w", resourceName, err)
+		}
+		addMemberToPubSubPolicy(currPolicy, sa, pubsubRolePrefix+role, condition.toPubsubExpr())
+		if _, err := p.subscriptions.SetIamPolicy(resourceName, &pubsub.SetIamPolicyRequest{Policy: currPolicy}).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("setting iam policy for subscription %q: %w", resourceName, err)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unknown scope %v", scope)
+	}
+}
+
+func (c *Condition) toExpr() *cloudres.Expr {
+	if c == nil {
+		return nil
+	}
+	return &cloudres.Expr{Expression: c.Expression, Title: c.Title, Description: c.Description}
+}
+
+func (c *Condition) toPubsubExpr() *pubsub.Expr {
+	if c == nil {
+		return nil
+	}
+	return &pubsub.Expr{Expression: c.Expression, Title: c.Title, Description: c.Description}
+}
@@ -0,0 +1,96 @@
+package iamprobe
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	iam "google.golang.org/api/iam/v1"
+)
+
+// JWT is the subset of a service account key file this package needs in
+// order to determine which project it belongs to.
+type JWT struct {
+	Type                    string `json:"type"`
+	ProjectID               string `json:"project_id"`
+	PrivateKeyID            string `json:"private_key_id"`
+	PrivateKey              string `json:"private_key"`
+	ClientEmail             string `json:"client_email"`
+	ClientID                string `json:"client_id"`
+	AuthURI                 string `json:"auth_uri"`
+	TokenURI                string `json:"token_uri"`
+	AuthProviderX509CertURL string `json:"auth_provider_x509_cert_url"`
+	ClientX509CertURL       string `json:"client_x509_cert_url"`
+}
+
+// JWTFromFile reads and parses a service account key file.
+func JWTFromFile(filePath string) (JWT, error) {
+	var jwt JWT
+
+	raw, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return jwt, fmt.Errorf("reading credentials file %q: %w", filePath, err)
+	}
+	if err := json.Unmarshal(raw, &jwt); err != nil {
+		return jwt, fmt.Errorf("parsing credentials file %q: %w", filePath, err)
+	}
+	return jwt, nil
+}
+
+// CredentialSource builds an *http.Client authenticated as a particular
+// identity, scoped for the given OAuth2 scopes. Implementations let a
+// caller probe as a file-based service account key, an in-memory
+// credentials blob (workload identity federation, or a key minted by
+// Prober.CreateServiceAccountKey), or an impersonated service account,
+// without ever mutating process-global state like
+// GOOGLE_APPLICATION_CREDENTIALS.
+type CredentialSource interface {
+	HTTPClient(ctx context.Context, scopes ...string) (*http.Client, error)
+}
+
+// FileCredentials builds a client from a service account JSON key file on
+// disk.
+type FileCredentials struct {
+	Path string
+}
+
+// HTTPClient implements CredentialSource.
+func (f FileCredentials) HTTPClient(ctx context.Context, scopes ...string) (*http.Client, error) {
+	raw, err := ioutil.ReadFile(f.Path)
+	if err != nil {
+		return nil, fmt.Errorf("reading credentials file %q: %w", f.Path, err)
+	}
+	return JSONCredentials{JSON: raw}.HTTPClient(ctx, scopes...)
+}
+
+// JSONCredentials builds a client from an in-memory credentials JSON blob
+// -- a service account key, or a workload identity federation config --
+// without it ever touching disk.
+type JSONCredentials struct {
+	JSON []byte
+}
+
+// HTTPClient implements CredentialSource.
+func (j JSONCredentials) HTTPClient(ctx context.Context, scopes ...string) (*http.Client, error) {
+	creds, err := google.CredentialsFromJSON(ctx, j.JSON, scopes...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing credentials JSON: %w", err)
+	}
+	return oauth2.NewClient(ctx, creds.TokenSource), nil
+}
+
+// CredentialSourceFromKey builds a CredentialSource from a service account
+// key minted by Prober.CreateServiceAccountKey, without ever writing it to
+// disk.
+func CredentialSourceFromKey(key *iam.ServiceAccountKey) (CredentialSource, error) {
+	raw, err := base64.StdEncoding.DecodeString(key.PrivateKeyData)
+	if err != nil {
+		return nil, fmt.Errorf("decoding service account key: %w", err)
+	}
+	return JSONCredentials{JSON: raw}, nil
+}
@@ -0,0 +1,62 @@
+package iamprobe
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"google.golang.org/api/option"
+)
+
+// DataPlaneResult is the outcome of VerifyDataPlane: whether the publish
+// and receive halves of the round trip succeeded, and the error returned
+// by the publish or receive call if either failed. If no message arrives
+// before the timeout, Received is false and Err is nil.
+type DataPlaneResult struct {
+	Published bool
+	Received  bool
+	Err       error
+}
+
+// VerifyDataPlane publishes a message to topicName and attempts to receive
+// it on subscriptionName within timeout, using the identity described by
+// clientOpts (e.g. option.WithHTTPClient with a client built from the
+// service account under test's CredentialSource). TestIamPermissions only reports what the IAM system thinks
+// is allowed; this exercises the actual Pub/Sub broker to catch divergence
+// between policy evaluation and data-plane enforcement. topicName and
+// subscriptionName may be either short IDs or full resource names.
+func VerifyDataPlane(ctx context.Context, projectID string, topicName, subscriptionName string, timeout time.Duration, clientOpts ...option.ClientOption) (*DataPlaneResult, error) {
+	client, err := pubsub.NewClient(ctx, projectID, clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating pubsub client: %w", err)
+	}
+	defer client.Close()
+
+	result := &DataPlaneResult{}
+
+	topic := client.Topic(path.Base(topicName))
+	defer topic.Stop()
+
+	publishResult := topic.Publish(ctx, &pubsub.Message{Data: []byte("iamprobe data-plane check")})
+	if _, err := publishResult.Get(ctx); err != nil {
+		result.Err = fmt.Errorf("publishing: %w", err)
+		return result, nil
+	}
+	result.Published = true
+
+	recvCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	sub := client.Subscription(path.Base(subscriptionName))
+	err = sub.Receive(recvCtx, func(_ context.Context, m *pubsub.Message) {
+		m.Ack()
+		result.Received = true
+		cancel()
+	})
+	if err != nil && recvCtx.Err() == nil {
+		result.Err = fmt.Errorf("receiving: %w", err)
+	}
+	return result, nil
+}
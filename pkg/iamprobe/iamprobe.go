@@ -0,0 +1,210 @@
+// Package iamprobe probes effective Google Cloud IAM permissions for a
+// service account across a project and its Pub/Sub topics and
+// subscriptions. It exists to make it easy to demonstrate (and regression
+// test) the gap between a role grant and the permissions it actually confers
+// at a given resource scope.
+package iamprobe
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	cloudres "google.golang.org/api/cloudresourcemanager/v1"
+	iam "google.golang.org/api/iam/v1"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+const (
+	roleResourcePrefix    = "roles/"
+	projectResourcePrefix = "projects/"
+	pubsubRolePrefix      = "pubsub."
+	topicPrefix           = "topics/"
+	subscriptionPrefix    = "subscriptions/"
+	saBindingPrefix       = "serviceAccount:"
+)
+
+// Prober issues IAM grants and permission checks against a single GCP
+// project using a fixed set of Google API clients.
+type Prober struct {
+	// ProjectID is the project all resources are created in and probed
+	// against.
+	ProjectID string
+
+	projects        *cloudres.ProjectsService
+	topics          *pubsub.ProjectsTopicsService
+	subscriptions   *pubsub.ProjectsSubscriptionsService
+	serviceAccounts *iam.ProjectsServiceAccountsService
+	keys            *iam.ProjectsServiceAccountsKeysService
+}
+
+// NewProber builds a Prober that issues all of its requests using
+// httpClient, which must already be scoped for the Pub/Sub, IAM, and Cloud
+// Resource Manager APIs.
+func NewProber(httpClient *http.Client, projectID string) (*Prober, error) {
+	cloudResService, err := cloudres.New(httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("creating cloud resource manager service: %w", err)
+	}
+	iamService, err := iam.New(httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("creating iam service: %w", err)
+	}
+	pubsubService, err := pubsub.New(httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("creating pubsub service: %w", err)
+	}
+
+	return &Prober{
+		ProjectID:       projectID,
+		projects:        cloudres.NewProjectsService(cloudResService),
+		topics:          pubsub.NewProjectsTopicsService(pubsubService),
+		subscriptions:   pubsub.NewProjectsSubscriptionsService(pubsubService),
+		serviceAccounts: iam.NewProjectsServiceAccountsService(iamService),
+		keys:            iam.NewProjectsServiceAccountsKeysService(iamService),
+	}, nil
+}
+
+// NewProberFromSource builds a Prober authenticated via source, scoped for
+// the Pub/Sub, IAM, and Cloud Resource Manager APIs.
+func NewProberFromSource(ctx context.Context, source CredentialSource, projectID string) (*Prober, error) {
+	httpClient, err := source.HTTPClient(ctx, pubsub.PubsubScope, iam.CloudPlatformScope)
+	if err != nil {
+		return nil, fmt.Errorf("building http client: %w", err)
+	}
+	return NewProber(httpClient, projectID)
+}
+
+// Result is the outcome of probing a single resource's effective IAM
+// permissions: which of the checked permissions the caller has, and which
+// it was denied.
+type Result struct {
+	// Resource is the full resource name that was probed, e.g.
+	// "projects/my-project" or "projects/my-project/topics/my-topic".
+	Resource string
+	Allowed  []string
+	Denied   []string
+}
+
+func newResult(resource string, checked []string, allowed []string) *Result {
+	allowedSet := make(map[string]bool, len(allowed))
+	for _, perm := range allowed {
+		allowedSet[perm] = true
+	}
+
+	result := &Result{Resource: resource, Allowed: allowed}
+	for _, perm := range checked {
+		if !allowedSet[perm] {
+			result.Denied = append(result.Denied, perm)
+		}
+	}
+	return result
+}
+
+func projectResourceName(projectID string) string {
+	return projectResourcePrefix + projectID
+}
+
+func topicResourceName(projectID string, topicName string) string {
+	return projectResourceName(projectID) + "/" + topicPrefix + topicName
+}
+
+func subscriptionResourceName(projectID string, subscriptionName string) string {
+	return projectResourceName(projectID) + "/" + subscriptionPrefix + subscriptionName
+}
+
+// ProbeProject tests the project-level permissions a Pub/Sub user typically
+// needs (creating and listing subscriptions, and listing topics) for the
+// identity the Prober authenticates as, plus any extraPerms the caller also
+// wants checked.
+func (p *Prober) ProbeProject(ctx context.Context, extraPerms ...string) (*Result, error) {
+	checked := mergePerms(projectPermsToCheck(), extraPerms)
+	resp, err := p.projects.TestIamPermissions(projectResourceName(p.ProjectID), &cloudres.TestIamPermissionsRequest{
+		Permissions: checked,
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("testing project permissions: %w", err)
+	}
+	return newResult(projectResourceName(p.ProjectID), checked, resp.Permissions), nil
+}
+
+// ProbeTopic tests the permissions a Pub/Sub user typically needs against a
+// single topic, plus any extraPerms the caller also wants checked.
+func (p *Prober) ProbeTopic(ctx context.Context, topic *pubsub.Topic, extraPerms ...string) (*Result, error) {
+	checked := mergePerms(topicPermsToCheck(), extraPerms)
+	resp, err := p.topics.TestIamPermissions(topic.Name, &pubsub.TestIamPermissionsRequest{
+		Permissions: checked,
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("testing topic permissions: %w", err)
+	}
+	return newResult(topic.Name, checked, resp.Permissions), nil
+}
+
+// ProbeSubscription tests the permissions a Pub/Sub user typically needs
+// against a single subscription, plus any extraPerms the caller also wants
+// checked.
+func (p *Prober) ProbeSubscription(ctx context.Context, sub *pubsub.Subscription, extraPerms ...string) (*Result, error) {
+	checked := mergePerms(subscriptionPermsToCheck(), extraPerms)
+	resp, err := p.subscriptions.TestIamPermissions(sub.Name, &pubsub.TestIamPermissionsRequest{
+		Permissions: checked,
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("testing subscription permissions: %w", err)
+	}
+	return newResult(sub.Name, checked, resp.Permissions), nil
+}
+
+// mergePerms returns base with any permissions from extra it doesn't
+// already contain appended, so callers can widen the checked set without
+// dropping the curated defaults.
+func mergePerms(base []string, extra []string) []string {
+	seen := make(map[string]bool, len(base))
+	merged := make([]string, len(base), len(base)+len(extra))
+	copy(merged, base)
+	for _, perm := range base {
+		seen[perm] = true
+	}
+	for _, perm := range extra {
+		if !seen[perm] {
+			seen[perm] = true
+			merged = append(merged, perm)
+		}
+	}
+	return merged
+}
+
+func projectPermsToCheck() []string {
+	return []string{
+		"pubsub.subscriptions.create",
+		"pubsub.subscriptions.list",
+		"pubsub.topics.list",
+	}
+}
+
+func topicPermsToCheck() []string {
+	return []string{
+		"pubsub.topics.get",
+		"pubsub.topics.subscribe",
+		"pubsub.topics.publish",
+		"pubsub.topics.update",
+		"pubsub.topics.attachSubscription",
+		"pubsub.topics.delete",
+		"pubsub.topics.getIamPolicy",
+		"pubsub.topics.setIamPolicy",
+	}
+}
+
+// subscriptionPermsToCheck mirrors the permission set the
+// terraform-provider-google IAM acceptance tests exercise against a
+// subscription.
+func subscriptionPermsToCheck() []string {
+	return []string{
+		"pubsub.subscriptions.get",
+		"pubsub.subscriptions.consume",
+		"pubsub.subscriptions.update",
+		"pubsub.subscriptions.delete",
+		"pubsub.subscriptions.getIamPolicy",
+		"pubsub.subscriptions.setIamPolicy",
+	}
+}
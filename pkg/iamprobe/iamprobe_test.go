@@ -0,0 +1,24 @@
+package iamprobe
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergePermsAppendsOnlyNewPerms(t *testing.T) {
+	base := []string{"pubsub.topics.get", "pubsub.topics.publish"}
+	extra := []string{"pubsub.topics.publish", "pubsub.topics.setIamPolicy"}
+
+	got := mergePerms(base, extra)
+	want := []string{"pubsub.topics.get", "pubsub.topics.publish", "pubsub.topics.setIamPolicy"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("mergePerms(%v, %v) = %v, want %v", base, extra, got, want)
+	}
+}
+
+func TestMergePermsWithNoExtraReturnsBase(t *testing.T) {
+	base := []string{"pubsub.topics.get"}
+	if got := mergePerms(base, nil); !reflect.DeepEqual(got, base) {
+		t.Fatalf("mergePerms(%v, nil) = %v, want %v", base, got, base)
+	}
+}
@@ -0,0 +1,124 @@
+package iamprobe
+
+import (
+	"context"
+	"fmt"
+
+	cloudres "google.golang.org/api/cloudresourcemanager/v1"
+	iam "google.golang.org/api/iam/v1"
+	pubsub "google.golang.org/api/pubsub/v1"
+)
+
+// CreateServiceAccount creates a new service account named name in
+// p.ProjectID.
+func (p *Prober) CreateServiceAccount(ctx context.Context, name string) (*iam.ServiceAccount, error) {
+	sa, err := p.serviceAccounts.Create(projectResourceName(p.ProjectID), &iam.CreateServiceAccountRequest{
+		AccountId:      name,
+		ServiceAccount: &iam.ServiceAccount{DisplayName: name},
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("creating service account %q: %w", name, err)
+	}
+	return sa, nil
+}
+
+// DeleteServiceAccount deletes a service account previously created with
+// CreateServiceAccount.
+func (p *Prober) DeleteServiceAccount(ctx context.Context, sa *iam.ServiceAccount) error {
+	if _, err := p.serviceAccounts.Delete(sa.Name).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("deleting service account %q: %w", sa.Name, err)
+	}
+	return nil
+}
+
+// CreateServiceAccountKey mints a new key for sa.
+func (p *Prober) CreateServiceAccountKey(ctx context.Context, sa *iam.ServiceAccount) (*iam.ServiceAccountKey, error) {
+	key, err := p.keys.Create(sa.Name, &iam.CreateServiceAccountKeyRequest{}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("creating key for service account %q: %w", sa.Name, err)
+	}
+	return key, nil
+}
+
+// CreateTopic creates a new Pub/Sub topic named name in p.ProjectID.
+func (p *Prober) CreateTopic(ctx context.Context, name string) (*pubsub.Topic, error) {
+	topic, err := p.topics.Create(topicResourceName(p.ProjectID, name), &pubsub.Topic{
+		Name: name,
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("creating topic %q: %w", name, err)
+	}
+	return topic, nil
+}
+
+// DeleteTopic deletes a topic previously created with CreateTopic.
+func (p *Prober) DeleteTopic(ctx context.Context, topic *pubsub.Topic) error {
+	if _, err := p.topics.Delete(topic.Name).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("deleting topic %q: %w", topic.Name, err)
+	}
+	return nil
+}
+
+// CreateSubscription creates a new subscription named name against topic.
+func (p *Prober) CreateSubscription(ctx context.Context, name string, topic *pubsub.Topic) (*pubsub.Subscription, error) {
+	sub, err := p.subscriptions.Create(subscriptionResourceName(p.ProjectID, name), &pubsub.Subscription{
+		Topic: topic.Name,
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("creating subscription %q: %w", name, err)
+	}
+	return sub, nil
+}
+
+// DeleteSubscription deletes a subscription previously created with
+// CreateSubscription.
+func (p *Prober) DeleteSubscription(ctx context.Context, sub *pubsub.Subscription) error {
+	if _, err := p.subscriptions.Delete(sub.Name).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("deleting subscription %q: %w", sub.Name, err)
+	}
+	return nil
+}
+
+// GrantProjectRole grants role (without the "roles/" prefix) to sa at the
+// project scope.
+func (p *Prober) GrantProjectRole(ctx context.Context, sa *iam.ServiceAccount, role string) error {
+	currPolicy, err := p.projects.GetIamPolicy(p.ProjectID, &cloudres.GetIamPolicyRequest{
+		Options: &cloudres.GetPolicyOptions{RequestedPolicyVersion: conditionalPolicyVersion},
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("getting project iam policy: %w", err)
+	}
+	addMemberToPolicy(currPolicy, sa, role, nil)
+	if _, err := p.projects.SetIamPolicy(p.ProjectID, &cloudres.SetIamPolicyRequest{Policy: currPolicy}).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("setting project iam policy: %w", err)
+	}
+	return nil
+}
+
+// GrantTopicRole grants role (without the "pubsub." or "roles/" prefixes)
+// to sa on topic.
+func (p *Prober) GrantTopicRole(ctx context.Context, topic *pubsub.Topic, sa *iam.ServiceAccount, role string) error {
+	currPolicy, err := p.topics.GetIamPolicy(topic.Name).OptionsRequestedPolicyVersion(conditionalPolicyVersion).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("getting topic iam policy: %w", err)
+	}
+	addMemberToPubSubPolicy(currPolicy, sa, pubsubRolePrefix+role, nil)
+	if _, err := p.topics.SetIamPolicy(topic.Name, &pubsub.SetIamPolicyRequest{Policy: currPolicy}).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("setting topic iam policy: %w", err)
+	}
+	return nil
+}
+
+// GrantSubscriptionRole grants role (without the "pubsub." or "roles/"
+// prefixes) to sa on sub.
+func (p *Prober) GrantSubscriptionRole(ctx context.Context, sub *pubsub.Subscription, sa *iam.ServiceAccount, role string) error {
+	currPolicy, err := p.subscriptions.GetIamPolicy(sub.Name).OptionsRequestedPolicyVersion(conditionalPolicyVersion).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("getting subscription iam policy: %w", err)
+	}
+	addMemberToPubSubPolicy(currPolicy, sa, pubsubRolePrefix+role, nil)
+	if _, err := p.subscriptions.SetIamPolicy(sub.Name, &pubsub.SetIamPolicyRequest{Policy: currPolicy}).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("setting subscription iam policy: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	iam "google.golang.org/api/iam/v1"
+	pubsub "google.golang.org/api/pubsub/v1"
+
+	"github.com/slack/gcp-iam-test/pkg/iamprobe"
+)
+
+const (
+	probeRetryAttempts     = 6
+	probeRetryInitialDelay = 2 * time.Second
+)
+
+// Executor runs Scenarios against a single Prober, creating and tearing
+// down ephemeral resources with a unique name suffix per run so that
+// multiple scenarios can execute concurrently in one project without
+// colliding.
+type Executor struct {
+	Prober *iamprobe.Prober
+	names  *Space
+}
+
+// NewExecutor builds an Executor that runs scenarios against prober.
+func NewExecutor(prober *iamprobe.Prober) *Executor {
+	return &Executor{Prober: prober, names: NewSpace()}
+}
+
+// Run creates the service accounts and resources s declares, executes its
+// Steps in order, tears everything down, and returns a Report describing
+// which steps passed their assertions.
+func (e *Executor) Run(ctx context.Context, s *Scenario) (*Report, error) {
+	suffix := e.names.New()
+
+	serviceAccounts := map[string]*iam.ServiceAccount{}
+	for _, name := range s.ServiceAccounts {
+		sa, err := e.Prober.CreateServiceAccount(ctx, name+"-"+suffix)
+		if err != nil {
+			return nil, fmt.Errorf("creating service account %q: %w", name, err)
+		}
+		defer e.Prober.DeleteServiceAccount(ctx, sa)
+		serviceAccounts[name] = sa
+	}
+
+	topics := map[string]*pubsub.Topic{}
+	for _, name := range s.Topics {
+		topic, err := e.Prober.CreateTopic(ctx, name+"-"+suffix)
+		if err != nil {
+			return nil, fmt.Errorf("creating topic %q: %w", name, err)
+		}
+		defer e.Prober.DeleteTopic(ctx, topic)
+		topics[name] = topic
+	}
+
+	subscriptions := map[string]*pubsub.Subscription{}
+	for _, spec := range s.Subscriptions {
+		topic, ok := topics[spec.Topic]
+		if !ok {
+			return nil, fmt.Errorf("subscription %q references unknown topic %q", spec.Name, spec.Topic)
+		}
+		sub, err := e.Prober.CreateSubscription(ctx, spec.Name+"-"+suffix, topic)
+		if err != nil {
+			return nil, fmt.Errorf("creating subscription %q: %w", spec.Name, err)
+		}
+		defer e.Prober.DeleteSubscription(ctx, sub)
+		subscriptions[spec.Name] = sub
+	}
+
+	report := &Report{ScenarioName: s.Name}
+	for i, step := range s.Steps {
+		start := time.Now()
+		result := e.runStep(ctx, step, serviceAccounts, topics, subscriptions)
+		result.Duration = time.Since(start)
+		if result.Name == "" {
+			result.Name = fmt.Sprintf("step-%d", i)
+		}
+		report.Steps = append(report.Steps, *result)
+	}
+	return report, nil
+}
+
+func (e *Executor) runStep(ctx context.Context, step Step, serviceAccounts map[string]*iam.ServiceAccount, topics map[string]*pubsub.Topic, subscriptions map[string]*pubsub.Subscription) *StepResult {
+	result := &StepResult{Name: fmt.Sprintf("%s@%s:%s", step.Role, step.Scope, step.ServiceAccount), Passed: true}
+
+	sa, ok := serviceAccounts[step.ServiceAccount]
+	if !ok {
+		return fail(result, fmt.Errorf("step references unknown service account %q", step.ServiceAccount))
+	}
+
+	extraPerms := append(append([]string{}, step.AssertAllowed...), step.AssertDenied...)
+
+	var probed *iamprobe.Result
+	switch step.Scope {
+	case ScopeProject:
+		if err := e.Prober.GrantProjectRole(ctx, sa, step.Role); err != nil {
+			return fail(result, fmt.Errorf("granting project role %q: %w", step.Role, err))
+		}
+		if err := withBackoff(ctx, probeRetryAttempts, probeRetryInitialDelay, func() error {
+			var err error
+			probed, err = e.Prober.ProbeProject(ctx, extraPerms...)
+			return err
+		}); err != nil {
+			return fail(result, fmt.Errorf("probing project: %w", err))
+		}
+
+	case ScopeTopic:
+		topic, ok := topics[step.Resource]
+		if !ok {
+			return fail(result, fmt.Errorf("step references unknown topic %q", step.Resource))
+		}
+		if err := e.Prober.GrantTopicRole(ctx, topic, sa, step.Role); err != nil {
+			return fail(result, fmt.Errorf("granting topic role %q: %w", step.Role, err))
+		}
+		if err := withBackoff(ctx, probeRetryAttempts, probeRetryInitialDelay, func() error {
+			var err error
+			probed, err = e.Prober.ProbeTopic(ctx, topic, extraPerms...)
+			return err
+		}); err != nil {
+			return fail(result, fmt.Errorf("probing topic: %w", err))
+		}
+
+	case ScopeSubscription:
+		sub, ok := subscriptions[step.Resource]
+		if !ok {
+			return fail(result, fmt.Errorf("step references unknown subscription %q", step.Resource))
+		}
+		if err := e.Prober.GrantSubscriptionRole(ctx, sub, sa, step.Role); err != nil {
+			return fail(result, fmt.Errorf("granting subscription role %q: %w", step.Role, err))
+		}
+		if err := withBackoff(ctx, probeRetryAttempts, probeRetryInitialDelay, func() error {
+			var err error
+			probed, err = e.Prober.ProbeSubscription(ctx, sub, extraPerms...)
+			return err
+		}); err != nil {
+			return fail(result, fmt.Errorf("probing subscription: %w", err))
+		}
+
+	default:
+		return fail(result, fmt.Errorf("unknown scope %q", step.Scope))
+	}
+
+	allowed := toSet(probed.Allowed)
+	for _, perm := range step.AssertAllowed {
+		if !allowed[perm] {
+			result.Passed = false
+			result.Failures = append(result.Failures, fmt.Sprintf("expected %q to be allowed, was denied", perm))
+		}
+	}
+	for _, perm := range step.AssertDenied {
+		if allowed[perm] {
+			result.Passed = false
+			result.Failures = append(result.Failures, fmt.Sprintf("expected %q to be denied, was allowed", perm))
+		}
+	}
+	return result
+}
+
+func fail(result *StepResult, err error) *StepResult {
+	result.Passed = false
+	result.Failures = append(result.Failures, err.Error())
+	return result
+}
+
+func toSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
@@ -0,0 +1,33 @@
+package scenario
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Space generates short, unlikely-to-collide name suffixes so that
+// multiple scenarios can run concurrently in one project without clobbering
+// each other's ephemeral resources, mirroring uid.Space from the
+// google-cloud-go testing helpers.
+type Space struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// NewSpace creates a Space seeded from the current time.
+func NewSpace() *Space {
+	return &Space{rnd: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// New returns a new, fixed-width 11-character suffix ("xxxxxx-xxxx"). It is
+// kept short and predictable so that a generated resource name (prefix +
+// "-" + suffix) fits under the 30-character limit GCP imposes on service
+// account IDs for prefixes up to 18 characters; scenario authors declaring
+// longer service account names should budget for the suffix accordingly.
+func (s *Space) New() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fmt.Sprintf("%06x-%04x", time.Now().UnixNano()%0x1000000, s.rnd.Intn(0x10000))
+}
@@ -0,0 +1,49 @@
+package scenario
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestSpaceNewIsUnique(t *testing.T) {
+	s := NewSpace()
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		name := s.New()
+		if seen[name] {
+			t.Fatalf("Space.New produced duplicate suffix %q", name)
+		}
+		seen[name] = true
+	}
+}
+
+// serviceAccountIDPattern matches the regex GCP enforces on service account
+// IDs: https://cloud.google.com/iam/docs/reference/rest/v1/projects.serviceAccounts/create
+var serviceAccountIDPattern = regexp.MustCompile(`^[a-z][-a-z0-9]{4,28}[a-z0-9]$`)
+
+func TestSpaceNewFitsServiceAccountIDLimit(t *testing.T) {
+	s := NewSpace()
+	// "probe-subscriber" is longer than the example scenarios' "probe-sa"
+	// to prove the suffix leaves headroom for more descriptive names, not
+	// just the shortest realistic prefix.
+	const prefix = "probe-subscriber"
+	for i := 0; i < 1000; i++ {
+		name := prefix + "-" + s.New()
+		if len(name) > 30 {
+			t.Fatalf("generated service account ID %q is %d chars, want <= 30", name, len(name))
+		}
+		if !serviceAccountIDPattern.MatchString(name) {
+			t.Fatalf("generated service account ID %q does not match %s", name, serviceAccountIDPattern)
+		}
+	}
+}
+
+func TestSpaceNewIsFixedWidth(t *testing.T) {
+	s := NewSpace()
+	const wantLen = len("000000-0000")
+	for i := 0; i < 1000; i++ {
+		if got := s.New(); len(got) != wantLen {
+			t.Fatalf("Space.New() returned %q, want a %d-char suffix", got, wantLen)
+		}
+	}
+}
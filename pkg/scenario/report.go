@@ -0,0 +1,86 @@
+package scenario
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StepResult is the outcome of executing and asserting a single Step.
+type StepResult struct {
+	Name     string        `json:"name"`
+	Passed   bool          `json:"passed"`
+	Failures []string      `json:"failures,omitempty"`
+	Duration time.Duration `json:"durationNanos"`
+}
+
+// Report is the outcome of running an entire Scenario.
+type Report struct {
+	ScenarioName string       `json:"scenarioName"`
+	Steps        []StepResult `json:"steps"`
+}
+
+// Passed reports whether every step in the scenario passed its assertions.
+func (r *Report) Passed() bool {
+	for _, step := range r.Steps {
+		if !step.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// JSON renders the report as indented JSON.
+func (r *Report) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// junitTestSuite is the minimal subset of the JUnit XML schema that CI
+// systems (e.g. Buildkite, CircleCI) understand.
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnitXML renders the report as a JUnit XML test suite.
+func (r *Report) JUnitXML() ([]byte, error) {
+	suite := junitTestSuite{Name: r.ScenarioName, Tests: len(r.Steps)}
+	for _, step := range r.Steps {
+		tc := junitTestCase{
+			Name:      step.Name,
+			ClassName: r.ScenarioName,
+			Time:      step.Duration.Seconds(),
+		}
+		if !step.Passed {
+			suite.Failures++
+			tc.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d assertion(s) failed", len(step.Failures)),
+				Text:    strings.Join(step.Failures, "\n"),
+			}
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	out, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling junit report: %w", err)
+	}
+	return append([]byte(xml.Header), out...), nil
+}
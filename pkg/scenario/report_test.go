@@ -0,0 +1,71 @@
+package scenario
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+)
+
+func TestReportPassed(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []StepResult
+		want  bool
+	}{
+		{"no steps", nil, true},
+		{"all passed", []StepResult{{Passed: true}, {Passed: true}}, true},
+		{"one failed", []StepResult{{Passed: true}, {Passed: false}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Report{Steps: tt.steps}
+			if got := r.Passed(); got != tt.want {
+				t.Fatalf("Passed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportJSON(t *testing.T) {
+	r := &Report{ScenarioName: "example", Steps: []StepResult{
+		{Name: "step-0", Passed: true, Duration: time.Second},
+	}}
+	out, err := r.JSON()
+	if err != nil {
+		t.Fatalf("JSON() returned error: %v", err)
+	}
+
+	var decoded Report
+	if err := json.Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+	if decoded.ScenarioName != r.ScenarioName || len(decoded.Steps) != 1 {
+		t.Fatalf("decoded report = %+v, want scenario %q with 1 step", decoded, r.ScenarioName)
+	}
+}
+
+func TestReportJUnitXML(t *testing.T) {
+	r := &Report{
+		ScenarioName: "example",
+		Steps: []StepResult{
+			{Name: "step-pass", Passed: true},
+			{Name: "step-fail", Passed: false, Failures: []string{"expected X to be allowed"}},
+		},
+	}
+	out, err := r.JUnitXML()
+	if err != nil {
+		t.Fatalf("JUnitXML() returned error: %v", err)
+	}
+
+	var suite junitTestSuite
+	if err := xml.Unmarshal(out, &suite); err != nil {
+		t.Fatalf("xml.Unmarshal failed: %v", err)
+	}
+	if suite.Tests != 2 {
+		t.Fatalf("Tests = %d, want 2", suite.Tests)
+	}
+	if suite.Failures != 1 {
+		t.Fatalf("Failures = %d, want 1", suite.Failures)
+	}
+}
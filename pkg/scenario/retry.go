@@ -0,0 +1,29 @@
+package scenario
+
+import (
+	"context"
+	"time"
+)
+
+// withBackoff retries fn up to attempts times with exponential backoff
+// starting at initial, tolerating the IAM propagation delay that
+// TestIamPermissions calls are prone to immediately after a policy change.
+func withBackoff(ctx context.Context, attempts int, initial time.Duration, fn func() error) error {
+	backoff := initial
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if i == attempts-1 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return err
+}
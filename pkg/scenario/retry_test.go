@@ -0,0 +1,66 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithBackoffSucceedsOnFirstTry(t *testing.T) {
+	calls := 0
+	err := withBackoff(context.Background(), 3, time.Millisecond, func() error {
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("withBackoff returned error: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("got %d calls, want 1", calls)
+	}
+}
+
+func TestWithBackoffRetriesUntilSuccess(t *testing.T) {
+	calls := 0
+	err := withBackoff(context.Background(), 3, time.Millisecond, func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("withBackoff returned error: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("got %d calls, want 3", calls)
+	}
+}
+
+func TestWithBackoffReturnsLastErrorAfterExhaustingAttempts(t *testing.T) {
+	wantErr := errors.New("still failing")
+	calls := 0
+	err := withBackoff(context.Background(), 3, time.Millisecond, func() error {
+		calls++
+		return wantErr
+	})
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("got error %v, want %v", err, wantErr)
+	}
+	if calls != 3 {
+		t.Fatalf("got %d calls, want 3", calls)
+	}
+}
+
+func TestWithBackoffStopsOnContextCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := withBackoff(ctx, 3, time.Millisecond, func() error {
+		return errors.New("nope")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got error %v, want context.Canceled", err)
+	}
+}
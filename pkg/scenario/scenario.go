@@ -0,0 +1,71 @@
+// Package scenario turns a declarative YAML description of service
+// accounts, Pub/Sub resources, and IAM role grants into a runnable IAM
+// regression test: the kind of check that would have caught the bug this
+// repo was written to demonstrate, that a topic-scoped
+// roles/pubsub.subscriber grant does not permit creating a subscription.
+package scenario
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Scope identifies where a role in a Step is granted.
+type Scope string
+
+// Scopes a Step's role grant can target.
+const (
+	ScopeProject      Scope = "project"
+	ScopeTopic        Scope = "topic"
+	ScopeSubscription Scope = "subscription"
+)
+
+// SubscriptionSpec declares an ephemeral subscription and the topic (by
+// name, as declared in Scenario.Topics) it attaches to.
+type SubscriptionSpec struct {
+	Name  string `yaml:"name"`
+	Topic string `yaml:"topic"`
+}
+
+// Step grants role to serviceAccount at scope (against resource, for the
+// topic and subscription scopes) and then asserts which of the listed
+// permissions are allowed or denied afterward.
+type Step struct {
+	ServiceAccount string   `yaml:"serviceAccount"`
+	Scope          Scope    `yaml:"scope"`
+	Resource       string   `yaml:"resource,omitempty"`
+	Role           string   `yaml:"role"`
+	AssertAllowed  []string `yaml:"assertAllowed,omitempty"`
+	AssertDenied   []string `yaml:"assertDenied,omitempty"`
+}
+
+// Scenario is a declarative IAM test matrix: the service accounts and
+// resources to create, and the sequence of grant-then-assert Steps to run
+// against them.
+type Scenario struct {
+	Name            string             `yaml:"name"`
+	ServiceAccounts []string           `yaml:"serviceAccounts"`
+	Topics          []string           `yaml:"topics"`
+	Subscriptions   []SubscriptionSpec `yaml:"subscriptions"`
+	Steps           []Step             `yaml:"steps"`
+}
+
+// Load parses a Scenario from raw YAML.
+func Load(raw []byte) (*Scenario, error) {
+	var s Scenario
+	if err := yaml.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("parsing scenario: %w", err)
+	}
+	return &s, nil
+}
+
+// LoadFile reads and parses a Scenario from a YAML file.
+func LoadFile(path string) (*Scenario, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading scenario file %q: %w", path, err)
+	}
+	return Load(raw)
+}